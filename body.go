@@ -0,0 +1,234 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// defaultBodyCaptureMaxBytes is used when BodyCapture.Enabled is true but
+// MaxBytes is left unset.
+const defaultBodyCaptureMaxBytes = 64 * 1024
+
+// BodyCapture controls whether and how a request or response body is
+// captured for logging.
+type BodyCapture struct {
+	// Enabled turns capture on for this body.
+	Enabled bool
+
+	// MaxBytes caps how much of the body is captured. Defaults to 64KB.
+	MaxBytes int
+
+	// ContentTypes restricts capture to bodies whose Content-Type matches one
+	// of these (e.g. "application/json", "application/x-www-form-urlencoded").
+	// If empty, every content type is captured.
+	ContentTypes []string
+
+	// TruncateMarker is logged as the value of a separate
+	// "request.body_truncated" / "response.body_truncated" attribute when the
+	// body was longer than MaxBytes. Defaults to "...(truncated)".
+	TruncateMarker string
+}
+
+func (bc BodyCapture) matchesContentType(contentType string) bool {
+	if len(bc.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, ct := range bc.ContentTypes {
+		if strings.EqualFold(ct, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bc BodyCapture) truncateMarker() string {
+	if bc.TruncateMarker != "" {
+		return bc.TruncateMarker
+	}
+	return "...(truncated)"
+}
+
+// bodyBufferPool pools the []byte buffers used to tee request/response
+// bodies during capture, keeping allocations flat under load.
+var bodyBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, defaultBodyCaptureMaxBytes)
+		return &buf
+	},
+}
+
+// captureRequestBody wraps req.Body in a teeing ReadCloser that buffers up
+// to cfg.MaxBytes while the handler chain reads the body normally. Call the
+// returned func after the request has been handled to get the logged
+// attribute value and whether the content was truncated.
+func captureRequestBody(req *http.Request, cfg BodyCapture) (*http.Request, func() (value any, truncated bool)) {
+	if !cfg.Enabled || req.Body == nil || !cfg.matchesContentType(req.Header.Get("Content-Type")) {
+		return req, func() (any, bool) { return nil, false }
+	}
+
+	bufPtr := bodyBufferPool.Get().(*[]byte)
+	*bufPtr = (*bufPtr)[:0]
+	capture := &capturingReadCloser{inner: req.Body, buf: bufPtr, max: cfg.MaxBytes}
+	req.Body = capture
+
+	return req, func() (any, bool) {
+		value := encodeCapturedBody(*capture.buf, req.Header.Get("Content-Type"))
+		truncated := capture.truncated
+		*capture.buf = (*capture.buf)[:0]
+		bodyBufferPool.Put(capture.buf)
+		return value, truncated
+	}
+}
+
+// capturingReadCloser tees up to max bytes of every Read into buf while
+// delegating to inner so the handler chain sees the original body unchanged.
+type capturingReadCloser struct {
+	inner     io.ReadCloser
+	buf       *[]byte
+	max       int
+	truncated bool
+}
+
+func (c *capturingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.inner.Read(p)
+	if n > 0 {
+		room := c.max - len(*c.buf)
+		if room > 0 {
+			take := n
+			if take > room {
+				take = room
+			}
+			*c.buf = append(*c.buf, p[:take]...)
+			if take < n {
+				c.truncated = true
+			}
+		} else {
+			c.truncated = true
+		}
+	}
+	return n, err
+}
+
+func (c *capturingReadCloser) Close() error {
+	return c.inner.Close()
+}
+
+// captureResponseWriter wraps an http.ResponseWriter, buffering up to max
+// bytes of the response body alongside whatever the handler writes.
+type captureResponseWriter struct {
+	http.ResponseWriter
+	buf       *[]byte
+	max       int
+	truncated bool
+}
+
+func newCaptureResponseWriter(w http.ResponseWriter, cfg BodyCapture) *captureResponseWriter {
+	bufPtr := bodyBufferPool.Get().(*[]byte)
+	*bufPtr = (*bufPtr)[:0]
+	return &captureResponseWriter{ResponseWriter: w, buf: bufPtr, max: cfg.MaxBytes}
+}
+
+func (c *captureResponseWriter) Write(p []byte) (int, error) {
+	room := c.max - len(*c.buf)
+	if room > 0 {
+		take := len(p)
+		if take > room {
+			take = room
+			c.truncated = true
+		}
+		*c.buf = append(*c.buf, p[:take]...)
+	} else if len(p) > 0 {
+		c.truncated = true
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// value returns the logged attribute for the captured response body and
+// releases the pooled buffer; call it once after the handler has returned.
+func (c *captureResponseWriter) value(contentType string) any {
+	value := encodeCapturedBody(*c.buf, contentType)
+	c.release()
+	return value
+}
+
+// release returns the pooled buffer without building a logged value, for
+// use when the captured body turns out not to be logged (e.g. its
+// Content-Type doesn't match BodyCapture.ContentTypes).
+func (c *captureResponseWriter) release() {
+	*c.buf = (*c.buf)[:0]
+	bodyBufferPool.Put(c.buf)
+}
+
+// redactBody runs a captured, already-decoded body value through redactors
+// before it's logged, walking JSON objects/arrays so NewJSONPathRedactor
+// dot-path rules (e.g. "user.ssn", "cards[*].pan") can match nested fields.
+// Non-JSON bodies are captured as a {"body_encoding", "data"} map whose data
+// is base64, so redactors see the base64 text rather than the original body.
+func redactBody(value any, redactors []Redactor) any {
+	if len(redactors) == 0 {
+		return value
+	}
+	return redactBodyValue("", value, redactors)
+}
+
+func redactBodyValue(path string, value any, redactors []Redactor) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			out[k] = redactBodyValue(childPath, child, redactors)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i] = redactBodyValue(path+"[*]", child, redactors)
+		}
+		return out
+	case string:
+		return redact(redactors, RedactScopeBody, path, v)
+	case nil:
+		return v
+	default:
+		// Stringify scalars (numbers, bools) so a JSONPath rule can still
+		// match e.g. a numeric SSN field; leave the original value in place
+		// when nothing matched.
+		s := fmt.Sprintf("%v", v)
+		if redacted := redact(redactors, RedactScopeBody, path, s); redacted != s {
+			return redacted
+		}
+		return v
+	}
+}
+
+// encodeCapturedBody renders a captured body as structured JSON when
+// possible, falling back to base64 with a body_encoding attribute.
+func encodeCapturedBody(captured []byte, contentType string) any {
+	if len(captured) == 0 {
+		return nil
+	}
+
+	if strings.Contains(contentType, "json") {
+		var v any
+		if err := json.Unmarshal(bytes.TrimSpace(captured), &v); err == nil {
+			return v
+		}
+	}
+
+	return map[string]any{
+		"body_encoding": "base64",
+		"data":          base64.StdEncoding.EncodeToString(captured),
+	}
+}
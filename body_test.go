@@ -0,0 +1,113 @@
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyCaptureMatchesContentType(t *testing.T) {
+	cfg := BodyCapture{ContentTypes: []string{"application/json"}}
+	if !cfg.matchesContentType("application/json; charset=utf-8") {
+		t.Fatalf("expected a json content type with params to match")
+	}
+	if cfg.matchesContentType("text/plain") {
+		t.Fatalf("expected text/plain not to match a json-only filter")
+	}
+}
+
+func TestCaptureRequestBodyTeesAndTruncates(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1,"b":2}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	req, capture := captureRequestBody(req, BodyCapture{Enabled: true, MaxBytes: 5})
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if string(body) != `{"a":1,"b":2}` {
+		t.Fatalf("expected the handler to still see the full body, got %q", body)
+	}
+
+	value, truncated := capture()
+	if !truncated {
+		t.Fatalf("expected capture to report truncation past MaxBytes")
+	}
+	if value == nil {
+		t.Fatalf("expected a non-nil captured value")
+	}
+}
+
+func TestCaptureRequestBodyDisabledIsNoop(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+	_, capture := captureRequestBody(req, BodyCapture{Enabled: false})
+	if value, truncated := capture(); value != nil || truncated {
+		t.Fatalf("expected a disabled BodyCapture to be a no-op, got value=%v truncated=%v", value, truncated)
+	}
+}
+
+func TestRedactBodyWalksNestedJSONPaths(t *testing.T) {
+	value := map[string]any{
+		"user": map[string]any{"ssn": "123-45-6789", "name": "ok"},
+		"cards": []any{
+			map[string]any{"pan": "4111111111111111"},
+			map[string]any{"pan": "4111111111111111"},
+		},
+	}
+
+	redactors := []Redactor{
+		NewJSONPathRedactor("user.ssn", RedactMode{}),
+		NewJSONPathRedactor("cards[*].pan", RedactMode{}),
+	}
+
+	got := redactBody(value, redactors).(map[string]any)
+	user := got["user"].(map[string]any)
+	if user["ssn"] != "[REDACTED]" {
+		t.Fatalf("expected user.ssn to be redacted, got %v", user["ssn"])
+	}
+	if user["name"] != "ok" {
+		t.Fatalf("expected user.name to pass through, got %v", user["name"])
+	}
+	cards := got["cards"].([]any)
+	for _, c := range cards {
+		if c.(map[string]any)["pan"] != "[REDACTED]" {
+			t.Fatalf("expected every card pan to be redacted, got %v", c)
+		}
+	}
+}
+
+func TestRequestLoggerCapturesAndRedactsBodies(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{
+		Options: Options{
+			RequestBody:  BodyCapture{Enabled: true, MaxBytes: defaultBodyCaptureMaxBytes},
+			ResponseBody: BodyCapture{Enabled: true, MaxBytes: defaultBodyCaptureMaxBytes},
+			Redactors:    []Redactor{NewJSONPathRedactor("ssn", RedactMode{})},
+		},
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+	}
+
+	handler := l.RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ssn":"123-45-6789","ok":true}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(`{"ssn":"000-00-0000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := buf.String()
+	if strings.Contains(out, "000-00-0000") || strings.Contains(out, "123-45-6789") {
+		t.Fatalf("expected both request and response bodies to have ssn redacted, got %s", out)
+	}
+	if !strings.Contains(out, `"request.body"`) || !strings.Contains(out, `"response.body"`) {
+		t.Fatalf("expected request.body and response.body attributes in the log line, got %s", out)
+	}
+}
@@ -0,0 +1,121 @@
+package httplog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// consoleHandler is a slog.Handler that renders one human-readable line per
+// record (time, level, message, then "key=value" attrs), with the level
+// coloured when it's one of the four standard slog levels. It backs
+// SinkFormatConsole.
+type consoleHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Level
+	attrs  []slog.Attr
+	groups []string
+}
+
+func newConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *consoleHandler {
+	var level slog.Level
+	if opts != nil && opts.Level != nil {
+		level = opts.Level.Level()
+	}
+	return &consoleHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Time.Format("15:04:05.000"))
+	b.WriteByte(' ')
+	b.WriteString(consoleLevelLabel(r.Level))
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		writeConsoleAttr(&b, h.groups, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeConsoleAttr(&b, h.groups, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	return &clone
+}
+
+// consoleLevelLabel renders level as a fixed-width, colour-coded label for
+// the four standard slog levels; anything else (a custom level) is rendered
+// uncoloured.
+func consoleLevelLabel(level slog.Level) string {
+	var color string
+	switch level {
+	case slog.LevelDebug:
+		color = "\x1b[90m" // gray: debug
+	case slog.LevelInfo:
+		color = "\x1b[36m" // cyan: info
+	case slog.LevelWarn:
+		color = "\x1b[33m" // yellow: warn
+	case slog.LevelError:
+		color = "\x1b[31m" // red: error
+	default:
+		return padLevel(level.String())
+	}
+	return color + padLevel(level.String()) + "\x1b[0m"
+}
+
+func padLevel(s string) string {
+	const width = 5
+	if len(s) >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// writeConsoleAttr appends " key=value" for a, joining groups with "." into
+// the key and quoting values that contain whitespace or quotes.
+func writeConsoleAttr(b *strings.Builder, groups []string, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	b.WriteByte(' ')
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(formatConsoleValue(a.Value))
+}
+
+func formatConsoleValue(v slog.Value) string {
+	s := v.String()
+	if strings.ContainsAny(s, " \t\r\n\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
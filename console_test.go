@@ -0,0 +1,55 @@
+package httplog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestConsoleHandlerRendersLevelMessageAndAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	h := newConsoleHandler(&buf, nil)
+	slog.New(h).With("request_id", "abc").Info("request", "status", 200)
+
+	out := buf.String()
+	if !strings.Contains(out, "request") || !strings.Contains(out, "request_id=abc") || !strings.Contains(out, "status=200") {
+		t.Fatalf("expected message and attrs in the rendered line, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[36m") {
+		t.Fatalf("expected the info level to be colour-coded, got %q", out)
+	}
+}
+
+func TestConsoleHandlerQuotesValuesWithSpaces(t *testing.T) {
+	var buf bytes.Buffer
+	h := newConsoleHandler(&buf, nil)
+	slog.New(h).Info("request", "user_agent", "Mozilla/5.0 test")
+
+	if !strings.Contains(buf.String(), `user_agent="Mozilla/5.0 test"`) {
+		t.Fatalf("expected a value containing spaces to be quoted, got %q", buf.String())
+	}
+}
+
+func TestConsoleHandlerWithGroupPrefixesKeys(t *testing.T) {
+	var buf bytes.Buffer
+	h := newConsoleHandler(&buf, nil)
+	slog.New(h).WithGroup("request").Info("hello", "method", "GET")
+
+	if !strings.Contains(buf.String(), "request.method=GET") {
+		t.Fatalf("expected WithGroup to prefix attr keys, got %q", buf.String())
+	}
+}
+
+func TestNewEncodingHandlerConsoleIsDistinctFromText(t *testing.T) {
+	var consoleBuf, textBuf bytes.Buffer
+	consoleHandler := newEncodingHandler(&consoleBuf, SinkFormatConsole, slog.LevelInfo)
+	textHandler := newEncodingHandler(&textBuf, SinkFormatText, slog.LevelInfo)
+
+	slog.New(consoleHandler).Info("hello")
+	slog.New(textHandler).Info("hello")
+
+	if consoleBuf.String() == textBuf.String() {
+		t.Fatalf("expected SinkFormatConsole to render differently from SinkFormatText, both produced %q", consoleBuf.String())
+	}
+}
@@ -0,0 +1,192 @@
+package httplog
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Logger ties a slog.Logger to the Options that control how HTTP requests
+// are logged, and is the entry point for the RequestLogger middleware.
+type Logger struct {
+	Options Options
+	Logger  *slog.Logger
+
+	closeSinks        func() error
+	metricsMiddleware func(http.Handler) http.Handler
+	metricsRegisterer prometheus.Registerer
+	metricsCollectors []prometheus.Collector
+}
+
+// NewLogger constructs a Logger configured with opts.
+func NewLogger(opts Options) *Logger {
+	l := &Logger{}
+	l.Configure(opts)
+	return l
+}
+
+// Close releases any resources held by the Logger's sinks, such as open
+// files and the SIGUSR1 reopen hook. Safe to call on a Logger with no sinks.
+func (l *Logger) Close() error {
+	if l.closeSinks == nil {
+		return nil
+	}
+	return l.closeSinks()
+}
+
+// buildHandler constructs the slog.Handler backing the Logger from
+// opts.Sinks, falling back to a single JSON handler on stdout when no sinks
+// are configured or sink construction fails.
+func buildHandler(opts Options) (slog.Handler, func() error) {
+	if len(opts.Sinks) == 0 {
+		return slog.NewJSONHandler(os.Stdout, nil), func() error { return nil }
+	}
+
+	handler, closeFn, err := NewFanoutHandler(opts.Sinks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "httplog: falling back to stdout, failed to build sinks: %v\n", err)
+		return slog.NewJSONHandler(os.Stdout, nil), func() error { return nil }
+	}
+	return handler, closeFn
+}
+
+// RequestLogger returns middleware that logs each request handled by next
+// through l.
+func (l *Logger) RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		req, requestBody := captureRequestBody(req, l.Options.RequestBody)
+
+		ww := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		var responseBody *captureResponseWriter
+		var rw http.ResponseWriter = ww
+		if l.Options.ResponseBody.Enabled {
+			responseBody = newCaptureResponseWriter(ww, l.Options.ResponseBody)
+			rw = responseBody
+		}
+
+		entry := l.Logger
+		if l.Options.TraceIDFieldName != "" || l.Options.SpanIDFieldName != "" {
+			tc := extractTraceContext(req)
+			req = req.WithContext(withTraceContext(req.Context(), tc))
+
+			var traceAttrs []any
+			if l.Options.TraceIDFieldName != "" {
+				traceAttrs = append(traceAttrs, l.Options.TraceIDFieldName, tc.TraceID)
+			}
+			if l.Options.SpanIDFieldName != "" {
+				traceAttrs = append(traceAttrs, l.Options.SpanIDFieldName, tc.SpanID)
+			}
+			entry = entry.With(traceAttrs...)
+		}
+		req = req.WithContext(withLogEntry(req.Context(), entry))
+
+		next.ServeHTTP(rw, req)
+
+		level := statusLevel(ww.status)
+		route := requestRoute(req)
+		if sampler := l.Options.Sampling.Sampler; sampler != nil && !sampler.Sample(route, level) {
+			if hook := l.Options.Sampling.Hook; hook != nil {
+				hook(route, level)
+			}
+			requestBody()
+			if responseBody != nil {
+				responseBody.release()
+			}
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", redact(l.Options.Redactors, RedactScopePath, "", req.URL.Path)),
+			slog.Int("status", ww.status),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if !l.Options.Concise {
+			attrs = append(attrs, slog.String("remote_addr", req.RemoteAddr), slog.String("user_agent", req.UserAgent()))
+			if req.URL.RawQuery != "" {
+				attrs = append(attrs, slog.Any("query", loggedQuery(req.URL.Query(), l.Options.Redactors)))
+			}
+		}
+		if l.Options.RequestHeaders {
+			attrs = append(attrs, slog.Any("request_headers", loggedHeaders(req.Header, l.Options.HideRequestHeaders, l.Options.Redactors)))
+		}
+		if l.Options.ResponseHeaders {
+			attrs = append(attrs, slog.Any("response_headers", loggedHeaders(ww.Header(), l.Options.HideRequestHeaders, l.Options.Redactors)))
+		}
+
+		if value, truncated := requestBody(); value != nil {
+			attrs = append(attrs, slog.Any("request.body", redactBody(value, l.Options.Redactors)))
+			if truncated {
+				attrs = append(attrs, slog.String("request.body_truncated", l.Options.RequestBody.truncateMarker()))
+			}
+		}
+		if responseBody != nil {
+			contentType := ww.Header().Get("Content-Type")
+			if l.Options.ResponseBody.matchesContentType(contentType) {
+				if value := responseBody.value(contentType); value != nil {
+					attrs = append(attrs, slog.Any("response.body", redactBody(value, l.Options.Redactors)))
+					if responseBody.truncated {
+						attrs = append(attrs, slog.String("response.body_truncated", l.Options.ResponseBody.truncateMarker()))
+					}
+				}
+			} else {
+				responseBody.release()
+			}
+		}
+
+		entry.LogAttrs(req.Context(), level, "request", attrs...)
+	})
+}
+
+// MetricsMiddleware wraps next with Prometheus metrics collection configured
+// via Options.Metrics. If Options.Metrics.Registerer is nil, it returns next
+// unchanged.
+func (l *Logger) MetricsMiddleware(next http.Handler) http.Handler {
+	if l.metricsMiddleware == nil {
+		return next
+	}
+	return l.metricsMiddleware(next)
+}
+
+// statusResponseWriter records the status code written by the handler so it
+// can be logged after the handler returns.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// statusLevel maps an HTTP status code to the slog.Level a request log line
+// is emitted at: 5xx as errors, 4xx as warnings, everything else info.
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// requestRoute returns the chi route pattern matched for req, so sampling
+// and metrics labels stay low-cardinality. Falls back to the raw path when
+// no chi route context is present (e.g. the request never matched a route).
+func requestRoute(req *http.Request) string {
+	if rctx := chi.RouteContext(req.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return req.URL.Path
+}
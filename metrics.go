@@ -0,0 +1,179 @@
+package httplog
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsConfig configures the Prometheus metrics collected by
+// Logger.MetricsMiddleware. If Registerer is nil, metrics are disabled.
+type MetricsConfig struct {
+	// Registerer is where the collectors are registered, typically
+	// prometheus.DefaultRegisterer or a dedicated *prometheus.Registry. If
+	// nil, Logger.MetricsMiddleware is a no-op.
+	Registerer prometheus.Registerer
+
+	MetricsOptions
+}
+
+// MetricsOptions configures NewMetricsMiddleware.
+type MetricsOptions struct {
+	// Namespace and Subsystem are prefixed to every metric name, following
+	// Prometheus naming conventions.
+	Namespace string
+	Subsystem string
+
+	// DurationBuckets overrides the histogram buckets used for
+	// http_request_duration_seconds. Defaults to prometheus.DefBuckets.
+	DurationBuckets []float64
+
+	// RouteLabelFunc extracts the route label for a request. Defaults to the
+	// chi route pattern via chi.RouteContext, falling back to req.URL.Path
+	// when no chi route context is present.
+	RouteLabelFunc func(req *http.Request) string
+}
+
+// metricsSet holds the collectors registered for a single
+// NewMetricsMiddleware call.
+type metricsSet struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	requestSize     *prometheus.HistogramVec
+	responseSize    *prometheus.HistogramVec
+	routeLabel      func(req *http.Request) string
+}
+
+// NewMetricsMiddleware returns middleware that records
+// http_requests_total, http_request_duration_seconds,
+// http_request_size_bytes, and http_response_size_bytes for every request,
+// registered against reg. Route labels use the chi route pattern rather
+// than the raw path to avoid cardinality explosions.
+//
+// Calling NewMetricsMiddleware twice against the same reg panics via
+// reg.MustRegister, since the collectors from the first call are never
+// unregistered; Logger.Configure handles that bookkeeping itself so it can
+// be called repeatedly with the same Options.Metrics.Registerer.
+func NewMetricsMiddleware(reg prometheus.Registerer, opts MetricsOptions) func(http.Handler) http.Handler {
+	ms := newMetricsSet(opts)
+	reg.MustRegister(ms.collectors()...)
+	return ms.middleware()
+}
+
+// newMetricsSet builds the collectors for a Logger's metrics without
+// registering them, so Logger.Configure can unregister a previous set
+// before registering the new one.
+func newMetricsSet(opts MetricsOptions) *metricsSet {
+	buckets := opts.DurationBuckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	routeLabel := opts.RouteLabelFunc
+	if routeLabel == nil {
+		routeLabel = defaultRouteLabel
+	}
+
+	labels := []string{"method", "route", "status"}
+
+	return &metricsSet{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests processed.",
+		}, labels),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency in seconds.",
+			Buckets:   buckets,
+		}, labels),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "http_request_size_bytes",
+			Help:      "HTTP request body size in bytes.",
+			Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "http_response_size_bytes",
+			Help:      "HTTP response body size in bytes.",
+			Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+		}, labels),
+		routeLabel: routeLabel,
+	}
+}
+
+// collectors returns every collector in ms, for (un)registration.
+func (ms *metricsSet) collectors() []prometheus.Collector {
+	return []prometheus.Collector{ms.requestsTotal, ms.requestDuration, ms.requestSize, ms.responseSize}
+}
+
+func (ms *metricsSet) middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			ww := &metricsResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(ww, req)
+
+			route := ms.routeLabel(req)
+			status := strconv.Itoa(ww.status)
+
+			ms.requestsTotal.WithLabelValues(req.Method, route, status).Inc()
+			ms.requestDuration.WithLabelValues(req.Method, route, status).Observe(time.Since(start).Seconds())
+			// req.ContentLength is -1 when the length is unknown (e.g. chunked
+			// or streamed uploads); observing that would corrupt the histogram
+			// with a negative value, so such requests are simply left out of
+			// this particular metric.
+			if req.ContentLength >= 0 {
+				ms.requestSize.WithLabelValues(req.Method, route, status).Observe(float64(req.ContentLength))
+			}
+			ms.responseSize.WithLabelValues(req.Method, route, status).Observe(float64(ww.bytesWritten))
+		})
+	}
+}
+
+// Handler returns a promhttp-compatible handler for reg, suitable for
+// mounting at /metrics.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// defaultRouteLabel reads the chi route pattern out of req's context,
+// falling back to the raw path when no chi route context is present (e.g.
+// the request never matched a route).
+func defaultRouteLabel(req *http.Request) string {
+	if rctx := chi.RouteContext(req.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return req.URL.Path
+}
+
+// metricsResponseWriter records the status code and bytes written so they
+// can be attached as metric labels/observations after the handler returns.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int64
+}
+
+func (w *metricsResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *metricsResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytesWritten += int64(n)
+	return n, err
+}
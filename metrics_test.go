@@ -0,0 +1,150 @@
+package httplog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsMiddlewareIgnoresUnknownContentLength(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mw := NewMetricsMiddleware(reg, MetricsOptions{})
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "http_request_size_bytes" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if sample := m.GetHistogram().GetSampleCount(); sample != 0 {
+				t.Fatalf("expected a request with unknown ContentLength to be excluded from http_request_size_bytes, got %d samples", sample)
+			}
+		}
+	}
+}
+
+func TestMetricsMiddlewareRecordsKnownContentLength(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mw := NewMetricsMiddleware(reg, MetricsOptions{})
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("hello"))
+	req.ContentLength = 5
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	var found bool
+	for _, mf := range families {
+		if mf.GetName() != "http_request_size_bytes" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetHistogram().GetSampleCount() == 1 {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a request with a known ContentLength to be recorded in http_request_size_bytes")
+	}
+}
+
+func TestLoggerMetricsMiddlewareNoopWithoutRegisterer(t *testing.T) {
+	l := &Logger{}
+	l.Configure(Options{})
+
+	called := false
+	handler := l.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called {
+		t.Fatalf("expected the wrapped handler to still run with metrics disabled")
+	}
+}
+
+func TestLoggerMetricsMiddlewareRegistersWhenConfigured(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	l := &Logger{}
+	l.Configure(Options{Metrics: MetricsConfig{Registerer: reg}})
+
+	handler := l.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	var total float64
+	for _, mf := range families {
+		if mf.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			total += m.GetCounter().GetValue()
+		}
+	}
+	if total != 1 {
+		t.Fatalf("expected http_requests_total to record 1 request, got %v", total)
+	}
+}
+
+func TestLoggerConfigureTwiceWithSameRegistererDoesNotPanic(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	l := &Logger{}
+	l.Configure(Options{Metrics: MetricsConfig{Registerer: reg}})
+	l.Configure(Options{Metrics: MetricsConfig{Registerer: reg}})
+
+	handler := l.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	var total float64
+	for _, mf := range families {
+		if mf.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			total += m.GetCounter().GetValue()
+		}
+	}
+	if total != 1 {
+		t.Fatalf("expected the second Configure's middleware to record the request, got total %v", total)
+	}
+}
+
+func TestDefaultRouteLabelFallsBackToPath(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	if got := defaultRouteLabel(req); got != "/widgets/42" {
+		t.Fatalf("expected the raw path as a fallback route label, got %q", got)
+	}
+}
@@ -66,6 +66,47 @@ type Options struct {
 	// the location in the program source code where the logger was called.
 	// If set to "" then it'll be disabled.
 	SourceFieldName string
+
+	// Sinks are additional log destinations (stdout, stderr, a file path,
+	// syslog, or an HTTP webhook) that records are fanned out to. Each Sink
+	// can filter by a minimum level, pick its own encoding, and rotate its
+	// own file independently of the others.
+	//
+	// If Sinks is empty, the Logger falls back to its default single
+	// destination behaviour.
+	Sinks []Sink
+
+	// TraceIDFieldName sets the field name for the OpenTelemetry trace ID
+	// attached to request logs. If set to "" the trace ID is not logged; trace
+	// correlation is disabled only when SpanIDFieldName is "" as well.
+	TraceIDFieldName string
+
+	// SpanIDFieldName sets the field name for the OpenTelemetry span ID
+	// attached to request logs. If set to "" the span ID is not logged; trace
+	// correlation is disabled only when TraceIDFieldName is "" as well.
+	SpanIDFieldName string
+
+	// Sampling configures which requests get logged beyond the all-or-nothing
+	// QuietDownRoutes silencer, via per-route deterministic sampling and/or a
+	// global token-bucket rate limit. If Sampling.Sampler is nil, sampling is disabled
+	// and every request is logged as before.
+	Sampling Sampling
+
+	// Redactors scrub sensitive values out of logged headers, query params,
+	// and (when body logging is enabled) request/response bodies, beyond
+	// what HideRequestHeaders already blanks.
+	Redactors []Redactor
+
+	// RequestBody enables capturing and logging of the request body.
+	RequestBody BodyCapture
+
+	// ResponseBody enables capturing and logging of the response body.
+	ResponseBody BodyCapture
+
+	// Metrics enables Prometheus metrics collection via
+	// Logger.MetricsMiddleware. If Metrics.Registerer is nil, metrics are
+	// disabled.
+	Metrics MetricsConfig
 }
 
 // Configure will set new options for the httplog instance and behaviour
@@ -89,6 +130,51 @@ func (l *Logger) Configure(opts Options) {
 		opts.HideRequestHeaders[i] = strings.ToLower(header)
 	}
 
+	if opts.RequestBody.Enabled && opts.RequestBody.MaxBytes == 0 {
+		opts.RequestBody.MaxBytes = defaultBodyCaptureMaxBytes
+	}
+	if opts.ResponseBody.Enabled && opts.ResponseBody.MaxBytes == 0 {
+		opts.ResponseBody.MaxBytes = defaultBodyCaptureMaxBytes
+	}
+
+	for i, sink := range opts.Sinks {
+		if sink.Format == "" {
+			sink.Format = SinkFormatJSON
+		}
+		if sink.Async && sink.AsyncBufferSize == 0 {
+			sink.AsyncBufferSize = defaultSinkBufferSize
+		}
+		opts.Sinks[i] = sink
+	}
+
+	if l.closeSinks != nil {
+		_ = l.closeSinks()
+	}
+	handler, closeSinks := buildHandler(opts)
+	l.Logger = slog.New(handler)
+	l.closeSinks = closeSinks
+
+	// Unregister the collectors from a previous Configure call before
+	// building new ones, so calling Configure again (e.g. on a config
+	// reload) against the same Registerer doesn't panic with "duplicate
+	// metrics collector registration attempted".
+	if l.metricsRegisterer != nil {
+		for _, c := range l.metricsCollectors {
+			l.metricsRegisterer.Unregister(c)
+		}
+	}
+	l.metricsMiddleware = nil
+	l.metricsRegisterer = nil
+	l.metricsCollectors = nil
+
+	if opts.Metrics.Registerer != nil {
+		ms := newMetricsSet(opts.Metrics.MetricsOptions)
+		opts.Metrics.Registerer.MustRegister(ms.collectors()...)
+		l.metricsMiddleware = ms.middleware()
+		l.metricsRegisterer = opts.Metrics.Registerer
+		l.metricsCollectors = ms.collectors()
+	}
+
 	l.Options = opts
 }
 
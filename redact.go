@@ -0,0 +1,295 @@
+package httplog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RedactScope identifies which part of a request/response a Redactor is
+// being asked to look at.
+type RedactScope int
+
+const (
+	RedactScopeHeader RedactScope = iota
+	RedactScopeQuery
+	RedactScopePath
+	RedactScopeBody
+)
+
+// RedactMode controls what a matched value is replaced with: either a fixed
+// placeholder, or a salted SHA-256 hash prefix so equal values can still be
+// correlated without leaking the original.
+type RedactMode struct {
+	// Hash replaces the value with a salted SHA-256 hash prefix instead of Placeholder.
+	Hash bool
+
+	// Placeholder is used when Hash is false. Defaults to "[REDACTED]".
+	Placeholder string
+
+	// Salt is mixed into the hash when Hash is true.
+	Salt string
+}
+
+func (m RedactMode) apply(value string) string {
+	if m.Hash {
+		sum := sha256.Sum256([]byte(m.Salt + value))
+		return "sha256:" + hex.EncodeToString(sum[:])[:12]
+	}
+	if m.Placeholder != "" {
+		return m.Placeholder
+	}
+	return "[REDACTED]"
+}
+
+// Redactor scrubs a single matching value out of a header, query param, URL
+// path, or JSON body field. Redact returns the (possibly unchanged) value
+// and whether it was modified.
+type Redactor interface {
+	Redact(scope RedactScope, key, value string) (string, bool)
+}
+
+// keyRedactor matches a single RedactScope by key, using glob-style matching
+// (path.Match semantics) against key.
+type keyRedactor struct {
+	scope RedactScope
+	glob  string
+	mode  RedactMode
+}
+
+func (r keyRedactor) Redact(scope RedactScope, key, value string) (string, bool) {
+	if scope != r.scope {
+		return value, false
+	}
+
+	matched := false
+	if r.scope == RedactScopeBody {
+		matched = bodyPathMatch(r.glob, key)
+	} else {
+		matched, _ = path.Match(r.glob, key)
+	}
+	if !matched {
+		return value, false
+	}
+	return r.mode.apply(value), true
+}
+
+// bodyPathMatch matches a dot-path glob (e.g. "user.ssn", "cards[*].pan")
+// against a concrete body path segment-by-segment, rather than handing the
+// whole string to path.Match: "[*]" is a literal array-index token produced
+// by redactBodyValue, not a path.Match character class, so each segment is
+// compared literally before falling back to path.Match for any other glob
+// characters within it (e.g. "addr*.city").
+func bodyPathMatch(glob, key string) bool {
+	globSegments := strings.Split(glob, ".")
+	keySegments := strings.Split(key, ".")
+	if len(globSegments) != len(keySegments) {
+		return false
+	}
+	for i, g := range globSegments {
+		if g == keySegments[i] {
+			continue
+		}
+		if ok, _ := path.Match(g, keySegments[i]); ok {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// NewHeaderRedactor redacts the value of any request/response header whose
+// name matches glob (e.g. "x-api-*"). Header names are lower-cased before
+// matching, so glob is written in lower case.
+func NewHeaderRedactor(glob string, mode RedactMode) Redactor {
+	return keyRedactor{scope: RedactScopeHeader, glob: glob, mode: mode}
+}
+
+// NewQueryRedactor redacts the value of any query parameter whose name
+// matches glob.
+func NewQueryRedactor(glob string, mode RedactMode) Redactor {
+	return keyRedactor{scope: RedactScopeQuery, glob: glob, mode: mode}
+}
+
+// NewJSONPathRedactor redacts the value of any JSON body field whose dot
+// path matches path (e.g. "user.ssn", "cards[*].pan"). It only applies when
+// request/response body logging is enabled.
+func NewJSONPathRedactor(jsonPath string, mode RedactMode) Redactor {
+	return keyRedactor{scope: RedactScopeBody, glob: jsonPath, mode: mode}
+}
+
+// pathRedactor redacts a URL path when it matches a regex, replacing the
+// whole logged path rather than a single field.
+type pathRedactor struct {
+	re   *regexp.Regexp
+	mode RedactMode
+}
+
+func (r pathRedactor) Redact(scope RedactScope, key, value string) (string, bool) {
+	if scope != RedactScopePath || !r.re.MatchString(value) {
+		return value, false
+	}
+	return r.mode.apply(value), true
+}
+
+// NewPathRedactor redacts the logged request path when it matches pattern,
+// e.g. to hide an account ID embedded in the URL.
+func NewPathRedactor(pattern string, mode RedactMode) (Redactor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return pathRedactor{re: re, mode: mode}, nil
+}
+
+// regexRedactor scans a value for substring matches of pattern, regardless
+// of scope or key, and replaces each match with replacement. It backs the
+// PII presets below.
+type regexRedactor struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+func (r regexRedactor) Redact(_ RedactScope, _, value string) (string, bool) {
+	if !r.re.MatchString(value) {
+		return value, false
+	}
+	return r.re.ReplaceAllString(value, r.replacement), true
+}
+
+// NewRegexRedactor replaces every substring of a header, query, path, or
+// body value matching pattern with replacement.
+func NewRegexRedactor(pattern, replacement string) Redactor {
+	return regexRedactor{re: regexp.MustCompile(pattern), replacement: replacement}
+}
+
+// PIIRedactors returns built-in redactors for common sensitive value shapes:
+// email addresses, Luhn-valid credit card numbers, JWTs, bearer tokens, and
+// AWS access keys.
+func PIIRedactors() []Redactor {
+	return []Redactor{
+		NewRegexRedactor(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`, "[REDACTED_EMAIL]"),
+		newCreditCardRedactor(),
+		NewRegexRedactor(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\b`, "[REDACTED_JWT]"),
+		NewRegexRedactor(`(?i)bearer\s+[A-Za-z0-9._\-]+`, "Bearer [REDACTED]"),
+		NewRegexRedactor(`\bAKIA[0-9A-Z]{16}\b`, "[REDACTED_AWS_KEY]"),
+	}
+}
+
+// cardCandidateRe matches runs of 13-19 digits (optionally grouped with
+// spaces or dashes) that *might* be a card number; luhnValid narrows that
+// down to ones that actually pass the Luhn check, so plain 13-19 digit runs
+// like order IDs, phone numbers, or timestamps aren't redacted.
+var cardCandidateRe = regexp.MustCompile(`\b(?:\d[ -]*?){13,19}\b`)
+
+// creditCardRedactor replaces only Luhn-valid card-shaped digit runs.
+type creditCardRedactor struct{}
+
+func newCreditCardRedactor() Redactor {
+	return creditCardRedactor{}
+}
+
+func (creditCardRedactor) Redact(_ RedactScope, _, value string) (string, bool) {
+	if !cardCandidateRe.MatchString(value) {
+		return value, false
+	}
+
+	changed := false
+	out := cardCandidateRe.ReplaceAllStringFunc(value, func(match string) string {
+		if !luhnValid(match) {
+			return match
+		}
+		changed = true
+		return "[REDACTED_CARD]"
+	})
+	return out, changed
+}
+
+// luhnValid reports whether the digits in s (ignoring spaces and dashes)
+// form a Luhn-valid number.
+func luhnValid(s string) bool {
+	var digits []int
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			continue
+		}
+		digits = append(digits, int(r-'0'))
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	parity := len(digits) % 2
+	for i, d := range digits {
+		if i%2 == parity {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// redact tries each Redactor in redactors in order and returns the result of
+// the first one that matches; later redactors are not applied.
+func redact(redactors []Redactor, scope RedactScope, key, value string) string {
+	for _, r := range redactors {
+		if redacted, ok := r.Redact(scope, key, value); ok {
+			return redacted
+		}
+	}
+	return value
+}
+
+// alwaysHiddenHeaders are blanked regardless of HideRequestHeaders, matching
+// the long-standing behaviour documented on Options.RequestHeaders.
+var alwaysHiddenHeaders = []string{"authorization", "cookie", "set-cookie"}
+
+// loggedHeaders builds the map of header name to value logged for a request
+// or response, blanking alwaysHiddenHeaders and hide, and running everything
+// else through redactors.
+func loggedHeaders(header http.Header, hide []string, redactors []Redactor) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		value := strings.Join(values, ",")
+
+		if isHiddenHeader(lower, hide) {
+			out[name] = "[HIDDEN]"
+			continue
+		}
+		out[name] = redact(redactors, RedactScopeHeader, lower, value)
+	}
+	return out
+}
+
+func isHiddenHeader(lower string, hide []string) bool {
+	for _, h := range alwaysHiddenHeaders {
+		if lower == h {
+			return true
+		}
+	}
+	for _, h := range hide {
+		if lower == h {
+			return true
+		}
+	}
+	return false
+}
+
+// loggedQuery builds the map of query parameter name to value logged for a
+// request, running every value through redactors.
+func loggedQuery(query url.Values, redactors []Redactor) map[string]string {
+	out := make(map[string]string, len(query))
+	for name, values := range query {
+		out[name] = redact(redactors, RedactScopeQuery, name, strings.Join(values, ","))
+	}
+	return out
+}
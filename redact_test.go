@@ -0,0 +1,137 @@
+package httplog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"valid visa", "4111111111111111", true},
+		{"valid with separators", "4111-1111-1111-1111", true},
+		{"invalid checksum", "4111111111111112", false},
+		{"too short", "123456789012", false},
+		{"too long", "12345678901234567890", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := luhnValid(tt.value); got != tt.want {
+				t.Fatalf("luhnValid(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPIIRedactorsOnlyRedactsLuhnValidCards(t *testing.T) {
+	redactors := PIIRedactors()
+
+	orderID := "order id 1234567890123 was placed"
+	if got := redact(redactors, RedactScopeBody, "", orderID); got != orderID {
+		t.Fatalf("expected a non-Luhn 13-digit order ID to survive redaction, got %q", got)
+	}
+
+	card := "card 4111111111111111 charged"
+	got := redact(redactors, RedactScopeBody, "", card)
+	if strings.Contains(got, "4111111111111111") {
+		t.Fatalf("expected a Luhn-valid card number to be redacted, got %q", got)
+	}
+}
+
+func TestNewRegexRedactorReplacesMatches(t *testing.T) {
+	r := NewRegexRedactor(`\d+`, "[N]")
+	got, changed := r.Redact(RedactScopeBody, "", "id 42")
+	if !changed || got != "id [N]" {
+		t.Fatalf("expected digits to be replaced, got %q changed=%v", got, changed)
+	}
+}
+
+func TestNewHeaderRedactorMatchesGlob(t *testing.T) {
+	r := NewHeaderRedactor("x-api-*", RedactMode{Placeholder: "[GONE]"})
+	got, changed := r.Redact(RedactScopeHeader, "x-api-key", "secret")
+	if !changed || got != "[GONE]" {
+		t.Fatalf("expected header glob match to redact, got %q changed=%v", got, changed)
+	}
+	if _, changed := r.Redact(RedactScopeHeader, "x-other", "secret"); changed {
+		t.Fatalf("expected non-matching header name to be left alone")
+	}
+}
+
+func TestRedactModeHash(t *testing.T) {
+	mode := RedactMode{Hash: true, Salt: "pepper"}
+	a := mode.apply("value")
+	b := mode.apply("value")
+	if a != b {
+		t.Fatalf("expected the same input to hash identically for correlation, got %q vs %q", a, b)
+	}
+	if a == "value" || !strings.HasPrefix(a, "sha256:") {
+		t.Fatalf("expected a sha256-prefixed hash, got %q", a)
+	}
+}
+
+func TestLoggedHeadersHidesSensitiveAndRedactsRest(t *testing.T) {
+	h := http.Header{
+		"Authorization": {"Bearer secret-token"},
+		"X-Api-Key":     {"abc123"},
+		"X-Normal":      {"fine"},
+	}
+
+	out := loggedHeaders(h, nil, []Redactor{NewHeaderRedactor("x-api-*", RedactMode{})})
+
+	if out["Authorization"] != "[HIDDEN]" {
+		t.Fatalf("expected Authorization to always be hidden, got %q", out["Authorization"])
+	}
+	if out["X-Api-Key"] != "[REDACTED]" {
+		t.Fatalf("expected X-Api-Key to be redacted, got %q", out["X-Api-Key"])
+	}
+	if out["X-Normal"] != "fine" {
+		t.Fatalf("expected X-Normal to pass through unchanged, got %q", out["X-Normal"])
+	}
+}
+
+func TestLoggedQueryRedactsMatchingParams(t *testing.T) {
+	query := url.Values{"token": {"abc"}, "page": {"2"}}
+	out := loggedQuery(query, []Redactor{NewQueryRedactor("token", RedactMode{})})
+
+	if out["token"] != "[REDACTED]" {
+		t.Fatalf("expected token query param to be redacted, got %q", out["token"])
+	}
+	if out["page"] != "2" {
+		t.Fatalf("expected page query param to pass through, got %q", out["page"])
+	}
+}
+
+func TestRequestLoggerRedactsHeadersAndQuery(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{
+		Options: Options{
+			RequestHeaders: true,
+			Redactors:      []Redactor{NewHeaderRedactor("x-api-key", RedactMode{}), NewQueryRedactor("token", RedactMode{})},
+		},
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+	}
+
+	handler := l.RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets?token=abc&page=2", nil)
+	req.Header.Set("X-Api-Key", "super-secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := buf.String()
+	if strings.Contains(out, "super-secret") {
+		t.Fatalf("expected the request log line to redact X-Api-Key, got %s", out)
+	}
+	if strings.Contains(out, `"token":"abc"`) {
+		t.Fatalf("expected the request log line to redact the token query param, got %s", out)
+	}
+}
@@ -0,0 +1,146 @@
+package httplog
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sampling configures the Sampler consulted by the request middleware before
+// it builds log attributes, plus an optional hook invoked for every record
+// the Sampler drops.
+type Sampling struct {
+	// Sampler decides whether a given route/level should be logged. If nil,
+	// sampling is disabled and every request is logged.
+	Sampler Sampler
+
+	// Hook, when set, is called once for every record the Sampler drops, so
+	// callers can emit a metric counting sampled-out lines.
+	Hook SamplingHook
+}
+
+// Sampler decides whether a request for route at level should be logged.
+type Sampler interface {
+	Sample(route string, level slog.Level) bool
+}
+
+// SamplingHook is invoked each time a record is dropped by a Sampler.
+type SamplingHook func(route string, level slog.Level)
+
+// RouteRule configures sampling for a single route pattern.
+type RouteRule struct {
+	// Route is matched exactly against the chi route pattern.
+	Route string
+
+	// KeepLevel is the minimum level that is always kept, bypassing the rate.
+	// Use this to always keep WARN+ while sampling INFO and below.
+	KeepLevel slog.Level
+
+	// Rate keeps deterministically 1 in N records below KeepLevel. A Rate of
+	// 0 or 1 keeps every record.
+	Rate int
+
+	// BurstFirst, when > 0, always keeps the first BurstFirst occurrences of
+	// the route before Rate sampling kicks in (zerolog's burst pattern).
+	BurstFirst int
+}
+
+// TokenBucket is a simple token-bucket rate limiter shared across routes
+// that aren't covered by a more specific RouteRule.
+type TokenBucket struct {
+	// Burst is the bucket capacity.
+	Burst int
+
+	// RefillPerSecond is the number of tokens added back per second, up to Burst.
+	RefillPerSecond float64
+}
+
+// NewCompositeSampler builds the default Sampler: each request is first
+// checked against rules (matched by exact route pattern), falling back to
+// bucket as a global rate limit for anything not covered by rules.
+func NewCompositeSampler(rules []RouteRule, bucket TokenBucket) Sampler {
+	cs := &compositeSampler{
+		rules: make(map[string]*routeState, len(rules)),
+	}
+	for _, r := range rules {
+		cs.rules[r.Route] = &routeState{rule: r}
+	}
+	if bucket.Burst > 0 {
+		cs.global = newTokenBucketState(bucket)
+	}
+	return cs
+}
+
+type compositeSampler struct {
+	mu     sync.Mutex
+	rules  map[string]*routeState
+	global *tokenBucketState
+}
+
+type routeState struct {
+	rule RouteRule
+	seen int
+}
+
+func (cs *compositeSampler) Sample(route string, level slog.Level) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if rs, ok := cs.rules[route]; ok {
+		if level >= rs.rule.KeepLevel {
+			return true
+		}
+		rs.seen++
+		if rs.rule.BurstFirst > 0 && rs.seen <= rs.rule.BurstFirst {
+			return true
+		}
+		if rs.rule.Rate <= 1 {
+			return true
+		}
+		return rs.seen%rs.rule.Rate == 0
+	}
+
+	if cs.global != nil {
+		return cs.global.Allow()
+	}
+
+	return true
+}
+
+// tokenBucketState is a standard token-bucket: tokens refill continuously up
+// to Burst, and Allow consumes one token if available.
+type tokenBucketState struct {
+	mu       sync.Mutex
+	tokens   float64
+	burst    float64
+	refill   float64
+	lastFill time.Time
+}
+
+func newTokenBucketState(cfg TokenBucket) *tokenBucketState {
+	return &tokenBucketState{
+		tokens:   float64(cfg.Burst),
+		burst:    float64(cfg.Burst),
+		refill:   cfg.RefillPerSecond,
+		lastFill: time.Now(),
+	}
+}
+
+func (tb *tokenBucketState) Allow() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(tb.lastFill).Seconds()
+	tb.lastFill = now
+	tb.tokens += elapsed * tb.refill
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
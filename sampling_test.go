@@ -0,0 +1,115 @@
+package httplog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompositeSamplerRateKeepsEveryNth(t *testing.T) {
+	sampler := NewCompositeSampler([]RouteRule{
+		{Route: "/healthz", KeepLevel: slog.LevelWarn, Rate: 4},
+	}, TokenBucket{})
+
+	var kept int
+	for i := 0; i < 8; i++ {
+		if sampler.Sample("/healthz", slog.LevelInfo) {
+			kept++
+		}
+	}
+	if kept != 2 {
+		t.Fatalf("expected 1-in-4 sampling to keep 2 of 8 records, kept %d", kept)
+	}
+}
+
+func TestCompositeSamplerKeepLevelBypassesRate(t *testing.T) {
+	sampler := NewCompositeSampler([]RouteRule{
+		{Route: "/healthz", KeepLevel: slog.LevelWarn, Rate: 100},
+	}, TokenBucket{})
+
+	for i := 0; i < 5; i++ {
+		if !sampler.Sample("/healthz", slog.LevelWarn) {
+			t.Fatalf("expected a WARN record to always bypass the sampling rate")
+		}
+	}
+}
+
+func TestCompositeSamplerBurstFirstAlwaysKeeps(t *testing.T) {
+	sampler := NewCompositeSampler([]RouteRule{
+		{Route: "/healthz", KeepLevel: slog.LevelWarn, Rate: 100, BurstFirst: 3},
+	}, TokenBucket{})
+
+	for i := 0; i < 3; i++ {
+		if !sampler.Sample("/healthz", slog.LevelInfo) {
+			t.Fatalf("expected the first 3 occurrences to be kept by BurstFirst")
+		}
+	}
+}
+
+func TestCompositeSamplerTokenBucketLimitsUncoveredRoutes(t *testing.T) {
+	sampler := NewCompositeSampler(nil, TokenBucket{Burst: 2, RefillPerSecond: 0})
+
+	if !sampler.Sample("/anything", slog.LevelInfo) || !sampler.Sample("/anything", slog.LevelInfo) {
+		t.Fatalf("expected the first 2 requests to be allowed by the burst capacity")
+	}
+	if sampler.Sample("/anything", slog.LevelInfo) {
+		t.Fatalf("expected the 3rd request to be dropped once the bucket is empty")
+	}
+}
+
+func TestRequestLoggerSkipsSampledOutRequests(t *testing.T) {
+	var buf bytes.Buffer
+	var droppedRoute string
+	var droppedLevel slog.Level
+
+	l := &Logger{
+		Options: Options{
+			Sampling: Sampling{
+				Sampler: samplerFunc(func(route string, level slog.Level) bool { return false }),
+				Hook: func(route string, level slog.Level) {
+					droppedRoute = route
+					droppedLevel = level
+				},
+			},
+		},
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+	}
+
+	handler := l.RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected no log line to be written for a sampled-out request, got %s", buf.String())
+	}
+	if droppedRoute != "/widgets" || droppedLevel != slog.LevelInfo {
+		t.Fatalf("expected SamplingHook to be called with the dropped route/level, got %q/%v", droppedRoute, droppedLevel)
+	}
+}
+
+func TestRequestLoggerLogsWhenSamplerAllows(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{
+		Options: Options{Sampling: Sampling{Sampler: samplerFunc(func(string, slog.Level) bool { return true })}},
+		Logger:  slog.New(slog.NewJSONHandler(&buf, nil)),
+	}
+
+	handler := l.RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/widgets", nil))
+
+	if !strings.Contains(buf.String(), `"path":"/widgets"`) {
+		t.Fatalf("expected the request log line to be written, got %s", buf.String())
+	}
+}
+
+type samplerFunc func(route string, level slog.Level) bool
+
+func (f samplerFunc) Sample(route string, level slog.Level) bool { return f(route, level) }
@@ -0,0 +1,316 @@
+package httplog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// defaultSinkBufferSize is the number of records buffered for a Sink with
+// Async enabled but no explicit AsyncBufferSize.
+const defaultSinkBufferSize = 1024
+
+// SinkFormat selects the encoding a Sink writes records in.
+type SinkFormat string
+
+const (
+	SinkFormatJSON    SinkFormat = "json"
+	SinkFormatText    SinkFormat = "text"
+	SinkFormatConsole SinkFormat = "console"
+)
+
+// SinkRotation configures rotation for a Sink whose Destination is a file
+// path. It is ignored for every other destination kind.
+type SinkRotation struct {
+	// MaxSizeMB is the size in megabytes a log file can reach before it's rotated.
+	MaxSizeMB int
+
+	// MaxAgeDays is the number of days to retain rotated files.
+	MaxAgeDays int
+
+	// MaxBackups is the number of rotated files to keep around.
+	MaxBackups int
+
+	// Compress gzips rotated files once they're no longer the active file.
+	Compress bool
+}
+
+// Sink describes a single log destination that the Logger fans records out
+// to, independently of any other configured Sink.
+type Sink struct {
+	// Destination is one of "stdout", "stderr", a file path, "syslog", or an
+	// "http://" / "https://" webhook URL.
+	Destination string
+
+	// MinLevel is the minimum level a record must have to reach this sink.
+	MinLevel slog.Level
+
+	// Format selects the encoding used for this sink. Defaults to SinkFormatJSON.
+	Format SinkFormat
+
+	// Rotation configures file rotation. Only meaningful when Destination is a file path.
+	Rotation SinkRotation
+
+	// Async buffers records in a fixed-size ring buffer and delivers them on
+	// a background goroutine, so a slow destination (e.g. a webhook) can
+	// never stall the request path. When the buffer is full the oldest
+	// buffered record is dropped to make room for the new one.
+	Async bool
+
+	// AsyncBufferSize is the capacity of the ring buffer when Async is true.
+	// Defaults to 1024.
+	AsyncBufferSize int
+}
+
+// fanoutHandler is a slog.Handler that dispatches every record to each of
+// its sinkHandlers whose MinLevel admits the record.
+type fanoutHandler struct {
+	sinks []*sinkHandler
+}
+
+// sinkHandler wraps the slog.Handler built for a single Sink, optionally
+// behind an async ring buffer.
+type sinkHandler struct {
+	sink    Sink
+	handler slog.Handler
+	buffer  *ringBuffer
+	closer  io.Closer
+}
+
+// NewFanoutHandler builds a slog.Handler that routes records to every sink
+// in sinks, honouring each sink's MinLevel, Format, rotation and async
+// settings. The returned close func blocks until every async buffer has
+// drained to its destination, then closes any open files; callers should
+// defer it.
+func NewFanoutHandler(sinks []Sink) (slog.Handler, func() error, error) {
+	fh := &fanoutHandler{}
+	var closers []io.Closer
+
+	for _, sink := range sinks {
+		sh, err := newSinkHandler(sink)
+		if err != nil {
+			return nil, nil, err
+		}
+		fh.sinks = append(fh.sinks, sh)
+		if sh.closer != nil {
+			closers = append(closers, sh.closer)
+		}
+	}
+
+	stopReopenHook := installReopenHook(fh)
+
+	return fh, func() error {
+		stopReopenHook()
+
+		var err error
+		for _, sh := range fh.sinks {
+			if sh.buffer != nil {
+				sh.buffer.Close()
+			}
+		}
+		for _, c := range closers {
+			if cerr := c.Close(); cerr != nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newSinkHandler(sink Sink) (*sinkHandler, error) {
+	w, closer, err := openSinkWriter(sink.Destination, sink.Rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := newEncodingHandler(w, sink.Format, sink.MinLevel)
+
+	sh := &sinkHandler{sink: sink, handler: handler, closer: closer}
+	if sink.Async {
+		size := sink.AsyncBufferSize
+		if size == 0 {
+			size = defaultSinkBufferSize
+		}
+		sh.buffer = newRingBuffer(size)
+	}
+	return sh, nil
+}
+
+func newEncodingHandler(w io.Writer, format SinkFormat, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case SinkFormatText:
+		return slog.NewTextHandler(w, opts)
+	case SinkFormatConsole:
+		return newConsoleHandler(w, opts)
+	default:
+		return slog.NewJSONHandler(w, opts)
+	}
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, sh := range h.sinks {
+		if level >= sh.sink.MinLevel {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var err error
+	for _, sh := range h.sinks {
+		if record.Level < sh.sink.MinLevel {
+			continue
+		}
+		if sh.buffer != nil {
+			// Pass sh.handler with the record, since the buffer is shared
+			// between clones: a record offered through a WithAttrs/WithGroup
+			// clone carries that clone's attrs/groups through to delivery.
+			sh.buffer.Offer(sh.handler, record)
+			continue
+		}
+		if herr := sh.handler.Handle(ctx, record); herr != nil {
+			err = herr
+		}
+	}
+	return err
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := &fanoutHandler{}
+	for _, sh := range h.sinks {
+		clone := *sh
+		clone.handler = sh.handler.WithAttrs(attrs)
+		out.sinks = append(out.sinks, &clone)
+	}
+	return out
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	out := &fanoutHandler{}
+	for _, sh := range h.sinks {
+		clone := *sh
+		clone.handler = sh.handler.WithGroup(name)
+		out.sinks = append(out.sinks, &clone)
+	}
+	return out
+}
+
+// bufferedRecord pairs a record with the handler it should be delivered
+// through, captured at Offer time so a WithAttrs/WithGroup clone's attrs and
+// groups survive the trip through the buffer.
+type bufferedRecord struct {
+	record  slog.Record
+	handler slog.Handler
+}
+
+// ringBuffer delivers records to the handler they were offered with on a
+// single background goroutine, dropping the oldest buffered record when
+// full so producers never block.
+type ringBuffer struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	records []bufferedRecord
+	cap     int
+	closed  bool
+	done    chan struct{}
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	rb := &ringBuffer{cap: capacity, done: make(chan struct{})}
+	rb.cond = sync.NewCond(&rb.mu)
+	go rb.run()
+	return rb
+}
+
+// Offer appends record paired with handler, dropping the oldest buffered
+// record if the ring is full.
+func (rb *ringBuffer) Offer(handler slog.Handler, record slog.Record) {
+	rb.mu.Lock()
+	if len(rb.records) >= rb.cap {
+		rb.records = rb.records[1:]
+	}
+	rb.records = append(rb.records, bufferedRecord{record: record, handler: handler})
+	rb.mu.Unlock()
+	rb.cond.Signal()
+}
+
+func (rb *ringBuffer) run() {
+	defer close(rb.done)
+	for {
+		rb.mu.Lock()
+		for len(rb.records) == 0 && !rb.closed {
+			rb.cond.Wait()
+		}
+		if rb.closed && len(rb.records) == 0 {
+			rb.mu.Unlock()
+			return
+		}
+		br := rb.records[0]
+		rb.records = rb.records[1:]
+		rb.mu.Unlock()
+
+		_ = br.handler.Handle(context.Background(), br.record)
+	}
+}
+
+// Close marks the buffer closed and blocks until run() has delivered every
+// record already queued, so callers can safely close the underlying
+// destination immediately afterwards.
+func (rb *ringBuffer) Close() {
+	rb.mu.Lock()
+	rb.closed = true
+	rb.mu.Unlock()
+	rb.cond.Signal()
+	<-rb.done
+}
+
+// installReopenHook registers a SIGUSR1 handler that reopens every
+// file-backed sink so external tools like logrotate can rename the
+// underlying file and have writers pick up the new inode. If fh has no
+// reopenable sinks, it registers nothing. The returned stop func undoes the
+// registration and stops the background goroutine; callers must invoke it
+// when the fanoutHandler is closed, or both leak for the life of the process.
+func installReopenHook(fh *fanoutHandler) (stop func()) {
+	var reopeners []reopener
+	for _, sh := range fh.sinks {
+		if r, ok := sh.closer.(reopener); ok {
+			reopeners = append(reopeners, r)
+		}
+	}
+	if len(reopeners) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				for _, r := range reopeners {
+					_ = r.Reopen()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// reopener is implemented by file-backed sink writers so they can be
+// reopened in place, e.g. after an external logrotate rename.
+type reopener interface {
+	Reopen() error
+}
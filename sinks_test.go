@@ -0,0 +1,266 @@
+package httplog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesAtMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := newRotatingFile(path, SinkRotation{MaxSizeMB: 0})
+	if err != nil {
+		t.Fatalf("newRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	// MaxSizeMB of 0 disables rotation; force a tiny threshold directly.
+	rf.rotation.MaxSizeMB = 1
+	const maxBytes = 1 * 1024 * 1024
+
+	if _, err := rf.Write(make([]byte, maxBytes-10)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := rf.Write(make([]byte, 20)); err != nil {
+		t.Fatalf("write triggering rotation: %v", err)
+	}
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly one rotated backup, got %d: %v", len(matches), matches)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat active file: %v", err)
+	}
+	if info.Size() != 20 {
+		t.Fatalf("expected active file to contain only the post-rotation write (20 bytes), got %d", info.Size())
+	}
+}
+
+func TestCompressFileOnceGzipsAndRemovesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log.20240102150405")
+	if err := os.WriteFile(path, []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	if err := compressFileOnce(path); err != nil {
+		t.Fatalf("compressFileOnce: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected original backup to be removed, stat err = %v", err)
+	}
+	if _, err := os.Stat(path + ".gz"); err != nil {
+		t.Fatalf("expected gzip file to exist: %v", err)
+	}
+}
+
+func TestPruneBackupsOnceRespectsMaxBackupsAndMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "app.log")
+
+	now := time.Now()
+	times := []time.Time{
+		now.Add(-72 * time.Hour),
+		now.Add(-48 * time.Hour),
+		now.Add(-24 * time.Hour),
+		now,
+	}
+	for _, ts := range times {
+		name := base + "." + ts.Format(backupTimeLayout)
+		if err := os.WriteFile(name, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write fixture %s: %v", name, err)
+		}
+	}
+
+	if err := pruneBackupsOnce(base, SinkRotation{MaxAgeDays: 2, MaxBackups: 10}); err != nil {
+		t.Fatalf("pruneBackupsOnce: %v", err)
+	}
+
+	matches, err := filepath.Glob(base + ".*")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 backups within MaxAgeDays to survive, got %d: %v", len(matches), matches)
+	}
+
+	if err := pruneBackupsOnce(base, SinkRotation{MaxBackups: 1}); err != nil {
+		t.Fatalf("pruneBackupsOnce: %v", err)
+	}
+	matches, err = filepath.Glob(base + ".*")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected MaxBackups=1 to keep only the newest backup, got %d: %v", len(matches), matches)
+	}
+	if !strings.HasSuffix(matches[0], times[len(times)-1].Format(backupTimeLayout)) {
+		t.Fatalf("expected the newest backup to survive, kept %v", matches)
+	}
+}
+
+func TestFanoutHandlerRoutesByMinLevel(t *testing.T) {
+	dir := t.TempDir()
+	infoPath := filepath.Join(dir, "info.log")
+	warnPath := filepath.Join(dir, "warn.log")
+
+	handler, closeFn, err := NewFanoutHandler([]Sink{
+		{Destination: infoPath, MinLevel: slog.LevelInfo, Format: SinkFormatJSON},
+		{Destination: warnPath, MinLevel: slog.LevelWarn, Format: SinkFormatJSON},
+	})
+	if err != nil {
+		t.Fatalf("NewFanoutHandler: %v", err)
+	}
+
+	logger := slog.New(handler)
+	logger.Info("info record")
+	logger.Warn("warn record")
+
+	if err := closeFn(); err != nil {
+		t.Fatalf("closeFn: %v", err)
+	}
+
+	infoContents, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("read info.log: %v", err)
+	}
+	if !strings.Contains(string(infoContents), "info record") || !strings.Contains(string(infoContents), "warn record") {
+		t.Fatalf("expected info.log to contain both records, got %q", infoContents)
+	}
+
+	warnContents, err := os.ReadFile(warnPath)
+	if err != nil {
+		t.Fatalf("read warn.log: %v", err)
+	}
+	if strings.Contains(string(warnContents), "info record") {
+		t.Fatalf("expected warn.log to exclude the info-level record, got %q", warnContents)
+	}
+	if !strings.Contains(string(warnContents), "warn record") {
+		t.Fatalf("expected warn.log to contain the warn record, got %q", warnContents)
+	}
+}
+
+func TestFanoutHandlerAsyncSinkKeepsWithAttrs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	handler, closeFn, err := NewFanoutHandler([]Sink{
+		{Destination: path, Format: SinkFormatJSON, Async: true},
+	})
+	if err != nil {
+		t.Fatalf("NewFanoutHandler: %v", err)
+	}
+
+	slog.New(handler).With("request_id", "x").Info("hello")
+
+	if err := closeFn(); err != nil {
+		t.Fatalf("closeFn: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read app.log: %v", err)
+	}
+	if !strings.Contains(string(contents), `"request_id":"x"`) {
+		t.Fatalf("expected an async sink to keep attrs added via .With(), got %q", contents)
+	}
+}
+
+func TestFanoutHandlerCloseDrainsAsyncSinkBeforeReturning(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	handler, closeFn, err := NewFanoutHandler([]Sink{
+		{Destination: path, Format: SinkFormatJSON, Async: true, AsyncBufferSize: 1000},
+	})
+	if err != nil {
+		t.Fatalf("NewFanoutHandler: %v", err)
+	}
+
+	logger := slog.New(handler)
+	const n = 500
+	for i := 0; i < n; i++ {
+		logger.Info("record")
+	}
+
+	if err := closeFn(); err != nil {
+		t.Fatalf("closeFn: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read app.log: %v", err)
+	}
+	if got := strings.Count(string(contents), "\"record\""); got != n {
+		t.Fatalf("expected closeFn to block until all %d records drained to disk, got %d", n, got)
+	}
+}
+
+func TestInstallReopenHookNoopWithoutReopenableSinks(t *testing.T) {
+	fh := &fanoutHandler{sinks: []*sinkHandler{{closer: discardCloser{}}}}
+
+	stop := installReopenHook(fh)
+	// Must be safe to call even though nothing was registered.
+	stop()
+}
+
+type discardCloser struct{}
+
+func (discardCloser) Close() error { return nil }
+
+func TestRingBufferDropsOldestWhenFull(t *testing.T) {
+	var handled []string
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := handlerFunc(func(_ context.Context, r slog.Record) error {
+		if r.Message == "first" {
+			close(started)
+			<-release // hold the first record so the buffer fills up behind it
+		}
+		handled = append(handled, r.Message)
+		return nil
+	})
+
+	rb := newRingBuffer(2)
+	rb.Offer(handler, makeRecord("first"))
+	<-started
+
+	rb.Offer(handler, makeRecord("second"))
+	rb.Offer(handler, makeRecord("third"))
+	rb.Offer(handler, makeRecord("fourth")) // ring cap is 2, so "second" should be dropped
+
+	close(release)
+	rb.Close()
+
+	if len(handled) == 0 {
+		t.Fatalf("expected at least the first record to be handled")
+	}
+	if got, want := strings.Join(handled, ","), "first,third,fourth"; got != want {
+		t.Fatalf("expected the oldest buffered record to be dropped, handled %s, want %s", got, want)
+	}
+}
+
+func makeRecord(msg string) slog.Record {
+	return slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
+}
+
+type handlerFunc func(context.Context, slog.Record) error
+
+func (f handlerFunc) Enabled(context.Context, slog.Level) bool        { return true }
+func (f handlerFunc) Handle(ctx context.Context, r slog.Record) error { return f(ctx, r) }
+func (f handlerFunc) WithAttrs([]slog.Attr) slog.Handler              { return f }
+func (f handlerFunc) WithGroup(string) slog.Handler                   { return f }
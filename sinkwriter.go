@@ -0,0 +1,258 @@
+package httplog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// openSinkWriter resolves a Sink.Destination to a writer. File destinations
+// are wrapped in a rotatingFile so Rotation and the SIGUSR1 reopen hook
+// apply; stdout/stderr are returned as-is with a no-op closer.
+func openSinkWriter(destination string, rotation SinkRotation) (io.Writer, io.Closer, error) {
+	switch destination {
+	case "", "stdout":
+		return os.Stdout, io.NopCloser(nil), nil
+	case "stderr":
+		return os.Stderr, io.NopCloser(nil), nil
+	case "syslog":
+		w, err := newSyslogWriter()
+		if err != nil {
+			return nil, nil, err
+		}
+		return w, w, nil
+	}
+
+	if strings.HasPrefix(destination, "http://") || strings.HasPrefix(destination, "https://") {
+		w := newWebhookWriter(destination)
+		return w, w, nil
+	}
+
+	rf, err := newRotatingFile(destination, rotation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rf, rf, nil
+}
+
+// rotatingFile is an io.Writer backed by an open file handle that rotates
+// once it exceeds Rotation.MaxSizeMB, and can be reopened in place to pick
+// up a file renamed by external logrotate.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	rotation SinkRotation
+	file     *os.File
+	size     int64
+}
+
+func newRotatingFile(path string, rotation SinkRotation) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, rotation: rotation}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	rf.file = f
+	rf.size = info.Size()
+	return nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if max := int64(rf.rotation.MaxSizeMB) * 1024 * 1024; max > 0 && rf.size+int64(len(p)) > max {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate renames the current file aside and opens a fresh one, pruning
+// backups beyond MaxBackups. The caller must hold rf.mu.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+
+	backup := fmt.Sprintf("%s.%s", rf.path, time.Now().Format(backupTimeLayout))
+	if err := os.Rename(rf.path, backup); err != nil {
+		return err
+	}
+	if rf.rotation.Compress {
+		go compressFile(backup)
+	}
+	go pruneBackups(rf.path, rf.rotation)
+
+	return rf.open()
+}
+
+// Reopen closes and reopens the file at the same path, for use after an
+// external tool (logrotate) has renamed it out from under us.
+func (rf *rotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file != nil {
+		_ = rf.file.Close()
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
+
+// backupTimeLayout is the timestamp format appended to a rotated backup's
+// file name. It sorts lexically in chronological order, which pruneBackups
+// relies on.
+const backupTimeLayout = "20060102150405"
+
+// compressFile gzips path in place, replacing it with path+".gz". Runs off
+// the hot path (rotate kicks it off in a goroutine), so errors are logged
+// rather than returned.
+func compressFile(path string) {
+	if err := compressFileOnce(path); err != nil {
+		fmt.Fprintf(os.Stderr, "httplog: failed to compress rotated log %s: %v\n", path, err)
+	}
+}
+
+func compressFileOnce(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// pruneBackups removes rotated backups of path beyond rotation.MaxBackups
+// and older than rotation.MaxAgeDays, oldest first. Either limit is skipped
+// when its field is <= 0.
+func pruneBackups(path string, rotation SinkRotation) {
+	if err := pruneBackupsOnce(path, rotation); err != nil {
+		fmt.Fprintf(os.Stderr, "httplog: failed to prune rotated backups for %s: %v\n", path, err)
+	}
+}
+
+func pruneBackupsOnce(path string, rotation SinkRotation) error {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return err
+	}
+	sort.Strings(matches) // backup suffix is the chronologically sortable backupTimeLayout
+
+	if rotation.MaxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -rotation.MaxAgeDays)
+		var kept []string
+		for _, m := range matches {
+			if t, ok := backupTime(path, m); ok && t.Before(cutoff) {
+				if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				continue
+			}
+			kept = append(kept, m)
+		}
+		matches = kept
+	}
+
+	if rotation.MaxBackups > 0 && len(matches) > rotation.MaxBackups {
+		for _, m := range matches[:len(matches)-rotation.MaxBackups] {
+			if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// backupTime extracts the rotation timestamp embedded in a backup's file
+// name by base, e.g. "app.log.20240102150405" or the gzip-compressed
+// "app.log.20240102150405.gz".
+func backupTime(base, name string) (time.Time, bool) {
+	suffix := strings.TrimPrefix(name, base+".")
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	t, err := time.ParseInLocation(backupTimeLayout, suffix, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// webhookWriter POSTs each write as the body of an HTTP request to url.
+// It's intended to sit behind an async Sink so a slow endpoint never stalls
+// the request path.
+type webhookWriter struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookWriter(url string) *webhookWriter {
+	return &webhookWriter{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (w *webhookWriter) Write(p []byte) (int, error) {
+	body := make([]byte, len(p))
+	copy(body, p)
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return len(p), nil
+}
+
+func (w *webhookWriter) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// newSyslogWriter dials the local syslog daemon and returns it as the
+// io.Writer/io.Closer pair for a "syslog" Sink destination.
+func newSyslogWriter() (*syslog.Writer, error) {
+	return syslog.New(syslog.LOG_INFO, "httplog")
+}
@@ -0,0 +1,110 @@
+package httplog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceparentRe matches a W3C traceparent header value:
+// version-traceid-spanid-flags, e.g. "00-<32 hex>-<16 hex>-01".
+var traceparentRe = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// requestTraceContext holds the trace/span correlation attached to a
+// single request's logger.
+type requestTraceContext struct {
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+}
+
+type traceContextKey struct{}
+
+// extractTraceContext resolves the correlation IDs for req, preferring an
+// OpenTelemetry span already present on req.Context(), falling back to a
+// W3C traceparent header, and finally generating a fresh traceparent-shaped
+// ID so the request's logs are still correlatable across services.
+func extractTraceContext(req *http.Request) requestTraceContext {
+	if span := trace.SpanFromContext(req.Context()); span.SpanContext().IsValid() {
+		sc := span.SpanContext()
+		flags := "00"
+		if sc.IsSampled() {
+			flags = "01"
+		}
+		return requestTraceContext{
+			TraceID:    sc.TraceID().String(),
+			SpanID:     sc.SpanID().String(),
+			TraceFlags: flags,
+		}
+	}
+
+	if tc, ok := parseTraceparent(req.Header.Get("traceparent")); ok {
+		return tc
+	}
+
+	return generateTraceContext()
+}
+
+// parseTraceparent parses the value of a W3C traceparent header.
+func parseTraceparent(header string) (requestTraceContext, bool) {
+	m := traceparentRe.FindStringSubmatch(header)
+	if m == nil {
+		return requestTraceContext{}, false
+	}
+	return requestTraceContext{TraceID: m[1], SpanID: m[2], TraceFlags: m[3]}, true
+}
+
+// generateTraceContext builds a fresh traceparent-shaped ID for a request
+// that arrives with neither an active span nor a traceparent header.
+func generateTraceContext() requestTraceContext {
+	traceID := make([]byte, 16)
+	spanID := make([]byte, 8)
+	_, _ = rand.Read(traceID)
+	_, _ = rand.Read(spanID)
+
+	return requestTraceContext{
+		TraceID:    hex.EncodeToString(traceID),
+		SpanID:     hex.EncodeToString(spanID),
+		TraceFlags: "01",
+	}
+}
+
+// withTraceContext attaches tc to ctx so it can be recovered by TraceContext
+// and inherited by downstream handlers.
+func withTraceContext(ctx context.Context, tc requestTraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContext returns the trace/span IDs recorded for the request's
+// logger, if trace correlation was enabled and a context was attached.
+func TraceContext(ctx context.Context) (traceID, spanID string, ok bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(requestTraceContext)
+	if !ok {
+		return "", "", false
+	}
+	return tc.TraceID, tc.SpanID, true
+}
+
+type logEntryKey struct{}
+
+// withLogEntry attaches logger to ctx so it can be recovered by LogEntry.
+func withLogEntry(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, logEntryKey{}, logger)
+}
+
+// LogEntry returns the request-scoped logger set up by RequestLogger,
+// already carrying this request's trace/span correlation (when enabled).
+// Downstream handlers should log through it rather than slog.Default() so
+// their lines stay correlated with the request. Falls back to slog.Default()
+// outside of a request handled by RequestLogger.
+func LogEntry(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(logEntryKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
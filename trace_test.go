@@ -0,0 +1,75 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparentValid(t *testing.T) {
+	tc, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok {
+		t.Fatalf("expected a valid traceparent to parse")
+	}
+	if tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.SpanID != "00f067aa0ba902b7" || tc.TraceFlags != "01" {
+		t.Fatalf("unexpected parse result: %+v", tc)
+	}
+}
+
+func TestParseTraceparentInvalid(t *testing.T) {
+	for _, header := range []string{"", "not-a-traceparent", "00-shorttrace-00f067aa0ba902b7-01"} {
+		if _, ok := parseTraceparent(header); ok {
+			t.Fatalf("expected header %q to fail to parse", header)
+		}
+	}
+}
+
+func TestExtractTraceContextGeneratesWhenAbsent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	tc := extractTraceContext(req)
+	if len(tc.TraceID) != 32 || len(tc.SpanID) != 16 {
+		t.Fatalf("expected a generated traceparent-shaped ID, got %+v", tc)
+	}
+}
+
+func TestLogEntryFallsBackToDefault(t *testing.T) {
+	if LogEntry(context.Background()) == nil {
+		t.Fatalf("expected LogEntry to fall back to slog.Default(), not nil")
+	}
+}
+
+func TestRequestLoggerAttachesTraceIDsAndLogEntry(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{
+		Options: Options{TraceIDFieldName: "trace_id", SpanIDFieldName: "span_id"},
+		Logger:  slog.New(slog.NewJSONHandler(&buf, nil)),
+	}
+
+	var gotTraceID string
+	handler := l.RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceID, _, _ = TraceContext(r.Context())
+		LogEntry(r.Context()).Info("inside handler")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if gotTraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected TraceContext to be populated inside the handler, got %q", gotTraceID)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Fatalf("expected request log line to carry trace_id, got %s", out)
+	}
+	if strings.Count(out, "4bf92f3577b34da6a3ce929d0e0e4736") < 2 {
+		t.Fatalf("expected both the handler's own log line and the request log line to carry the trace ID, got %s", out)
+	}
+}